@@ -0,0 +1,102 @@
+package summer
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// Logger is satisfied by anything that can report Start/Stop progress.
+// Debugf is used for routine progress, Errorf for failures that the graph
+// itself does not otherwise surface as an error.
+type Logger interface {
+	Debugf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+// Dependence describes a single dependency edge discovered during
+// injection: the struct field on Object that pointed at the dependency.
+type Dependence struct {
+	Field  string
+	Object *Dew
+}
+
+// Dew wraps a single value participating in the Graph, along with the
+// dependencies that were injected into it.
+type Dew struct {
+	Name         string
+	Value        interface{}
+	Dependencies []*Dependence
+}
+
+// String returns the Dew's Name if set, otherwise the type of its Value.
+func (d *Dew) String() string {
+	if d.Name != "" {
+		return d.Name
+	}
+	return fmt.Sprintf("%T", d.Value)
+}
+
+// Graph holds every Dew registered for dependency injection and lifecycle
+// management.
+type Graph struct {
+	// Logger, when set, receives debug and error output describing Start
+	// and Stop progress.
+	Logger Logger
+
+	// Concurrency bounds how many objects within a single dependency level
+	// may Start/Stop at once. 0 means unlimited, 1 preserves the original
+	// sequential behavior.
+	Concurrency int
+
+	// DisableRollbackOnStartError turns off the automatic rollback that
+	// otherwise follows a failed Start, stopping whatever had already come
+	// up in reverse level order. Rollback is on by default, so the zero
+	// value of a bare Graph{} gets it.
+	DisableRollbackOnStartError bool
+
+	// RollbackTimeout bounds the context used to run the automatic
+	// rollback. Zero means no timeout.
+	RollbackTimeout time.Duration
+
+	// ReadyPollInterval is the delay between successive calls to a
+	// started object's Ready method. Defaults to 10ms when zero.
+	ReadyPollInterval time.Duration
+
+	// ReadyPollBackoff multiplies ReadyPollInterval after each failed
+	// Ready poll. Values less than 1 (including the zero value) disable
+	// backoff, polling at a fixed interval.
+	ReadyPollBackoff float64
+
+	// Signals are the signals that Run listens for to trigger Reload. A
+	// nil slice defaults to just syscall.SIGHUP; add SIGUSR1/SIGUSR2 here
+	// to wire up custom reload-triggered hooks (e.g. dumping state).
+	Signals []os.Signal
+
+	// ReloadTimeout bounds the context that Reload passes to each
+	// Reloader, whether Reload is triggered by a signal in Run or called
+	// directly. Zero means no timeout.
+	ReloadTimeout time.Duration
+
+	// Hooks, when set, is invoked around every Open/Start/Stop/Close call,
+	// for metrics such as per-component latency histograms.
+	Hooks *Hooks
+
+	// TracerProvider, when set, opens a span around every Open/Start/Stop/
+	// Close call, for distributed traces of boot/shutdown sequences.
+	TracerProvider TracerProvider
+
+	objects []*Dew
+	started []*Dew
+	named   map[string]*Dew
+}
+
+// Objects returns every Dew registered on the Graph.
+func (g *Graph) Objects() []*Dew {
+	return g.objects
+}
+
+// Add registers a Dew on the Graph.
+func (g *Graph) Add(o *Dew) {
+	g.objects = append(g.objects, o)
+}
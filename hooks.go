@@ -0,0 +1,72 @@
+package summer
+
+import (
+	"context"
+	"time"
+)
+
+// Hooks lets callers observe Start/Stop progress, e.g. to record metrics.
+// Every callback is optional and is invoked around each Open/Start/Stop/
+// Close call the graph makes; phase is one of "open", "start", "stop",
+// "close". The Before variants run with err nil and dur 0, since the call
+// hasn't happened yet.
+type Hooks struct {
+	BeforeStart func(ctx context.Context, o *Dew, phase string, err error, dur time.Duration)
+	AfterStart  func(ctx context.Context, o *Dew, phase string, err error, dur time.Duration)
+	BeforeStop  func(ctx context.Context, o *Dew, phase string, err error, dur time.Duration)
+	AfterStop   func(ctx context.Context, o *Dew, phase string, err error, dur time.Duration)
+}
+
+// Span is returned by TracerProvider.Start and ended once the phase it was
+// started for completes.
+type Span interface {
+	End()
+}
+
+// TracerProvider wraps a tracer so the graph can open a span around every
+// Open/Start/Stop/Close call. OpenTelemetry's trace.Tracer can back this
+// behind a thin adapter that drops the variadic SpanStartOption/
+// SpanEndOption parameters summer doesn't need.
+type TracerProvider interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// instrument runs fn, opening a TracerProvider span and calling the
+// before/after Hooks around it. isStop selects between the Start and Stop
+// hook pairs.
+func (g *Graph) instrument(ctx context.Context, o *Dew, phase string, isStop bool, fn func(ctx context.Context) error) error {
+	spanCtx := ctx
+	var span Span
+	if g.TracerProvider != nil {
+		prefix := "summer.start/"
+		if isStop {
+			prefix = "summer.stop/"
+		}
+		spanCtx, span = g.TracerProvider.Start(ctx, prefix+o.String())
+	}
+
+	var before, after func(ctx context.Context, o *Dew, phase string, err error, dur time.Duration)
+	if g.Hooks != nil {
+		if isStop {
+			before, after = g.Hooks.BeforeStop, g.Hooks.AfterStop
+		} else {
+			before, after = g.Hooks.BeforeStart, g.Hooks.AfterStart
+		}
+	}
+
+	if before != nil {
+		before(spanCtx, o, phase, nil, 0)
+	}
+
+	callStart := time.Now()
+	err := fn(spanCtx)
+	dur := time.Since(callStart)
+
+	if after != nil {
+		after(spanCtx, o, phase, err, dur)
+	}
+	if span != nil {
+		span.End()
+	}
+	return err
+}
@@ -5,16 +5,24 @@ package summer
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
 	"sort"
+	"sync"
 	"syscall"
 	"time"
+
+	"golang.org/x/sync/errgroup"
 )
 
 const defaultTimeout = 15 * time.Second
 
+// defaultReadyPollInterval is used when polling a Ready object and
+// Graph.ReadyPollInterval is unset.
+const defaultReadyPollInterval = 10 * time.Millisecond
+
 // Opener defines the Open method, objects satisfying this interface will be
 // opened by Start.
 type Opener interface {
@@ -39,45 +47,229 @@ type Stopper interface {
 	Stop(ctx context.Context) error
 }
 
+// StartTimeout can be implemented by an Opener/Starter to bound its own
+// Open/Start/Ready with a child context of that duration. The child is
+// derived from the parent Start context, so an earlier parent deadline
+// still applies.
+type StartTimeout interface {
+	StartTimeout() time.Duration
+}
+
+// StopTimeout can be implemented by a Closer/Stopper to bound its own
+// Stop/Close with a child context of that duration. The child is derived
+// from the parent Stop context, so an earlier parent deadline still
+// applies.
+type StopTimeout interface {
+	StopTimeout() time.Duration
+}
+
+// Ready can be implemented by an Opener/Starter that needs to report
+// readiness separately from Open/Start returning. Once Open/Start succeeds,
+// the graph polls Ready until it returns nil or the object's start timeout
+// expires, at which point a non-nil result is treated as a start failure.
+type Ready interface {
+	Ready(ctx context.Context) error
+}
+
+// Reloader can be implemented by any object that needs to react to a
+// runtime reconfiguration signal without a full Stop/Start cycle. See
+// Graph.Signals and Graph.Reload.
+type Reloader interface {
+	Reload(ctx context.Context) error
+}
+
+// StartError is returned by Start when a Dew fails to start. Cause is the
+// error returned by the failing object's Open/Start (or by its Ready
+// poll, when it never became ready), and RollbackErrors
+// holds any errors encountered while unwinding the objects that had
+// already come up (see Graph.DisableRollbackOnStartError).
+type StartError struct {
+	Object         *Dew
+	Cause          error
+	RollbackErrors []error
+}
+
+func (e *StartError) Error() string {
+	errs := append([]error{fmt.Errorf("failed to start %s: %w", e.Object, e.Cause)}, e.RollbackErrors...)
+	return errors.Join(errs...).Error()
+}
+
+func (e *StartError) Unwrap() error {
+	return e.Cause
+}
+
+// objectStartError pins a Start/Open failure to the Dew that caused it, so
+// that it can be recovered from an errgroup's aggregated error.
+type objectStartError struct {
+	object *Dew
+	err    error
+}
+
+func (e *objectStartError) Error() string {
+	return e.err.Error()
+}
+
+func (e *objectStartError) Unwrap() error {
+	return e.err
+}
+
 // TryStart will start the graph, in the right order. It will call
 // Start or Open. It returns the list of objects that have been
 // successfully started. This can be used to stop only the
 // dependencies that have been correctly started.
+//
+// Objects within the same dependency level have no ordering constraints
+// between them, so they are started concurrently, bounded by
+// Graph.Concurrency. The graph still waits for an entire level to finish
+// before moving on to the next one.
 func (g *Graph) tryStart(ctx context.Context) error {
 	levels, err := levels(g.Objects())
 	if err != nil {
 		return err
 	}
 
-	var started []*Dew
+	var (
+		started   []*Dew
+		startedMu sync.Mutex
+	)
 	for i := len(levels) - 1; i >= 0; i-- {
 		level := levels[i]
+
+		levelCtx, cancel := context.WithCancel(ctx)
+		eg, egCtx := errgroup.WithContext(levelCtx)
+		if g.Concurrency > 0 {
+			eg.SetLimit(g.Concurrency)
+		}
+
 		for _, o := range level {
-			if openerO, ok := o.Value.(Opener); ok {
-				if g.Logger != nil {
-					g.Logger.Debugf("opening %s", o)
+			o := o
+			eg.Go(func() error {
+				objCtx := egCtx
+				if sto, ok := o.Value.(StartTimeout); ok {
+					if d := sto.StartTimeout(); d > 0 {
+						var objCancel context.CancelFunc
+						objCtx, objCancel = context.WithTimeout(egCtx, d)
+						defer objCancel()
+					}
 				}
-				if err := openerO.Open(ctx); err != nil {
-					g.started = started
-					return err
+
+				if openerO, ok := o.Value.(Opener); ok {
+					if g.Logger != nil {
+						g.Logger.Debugf("opening %s", o)
+					}
+					if err := g.instrument(objCtx, o, "open", false, openerO.Open); err != nil {
+						return &objectStartError{object: o, err: err}
+					}
 				}
-			}
-			if starterO, ok := o.Value.(Starter); ok {
-				if g.Logger != nil {
-					g.Logger.Debugf("starting %s", o)
+				if starterO, ok := o.Value.(Starter); ok {
+					if g.Logger != nil {
+						g.Logger.Debugf("starting %s", o)
+					}
+					if err := g.instrument(objCtx, o, "start", false, starterO.Start); err != nil {
+						return &objectStartError{object: o, err: err}
+					}
 				}
-				if err := starterO.Start(ctx); err != nil {
-					g.started = started
-					return err
+				// Open/Start have returned nil at this point, so the object
+				// must be recorded as started before the Ready poll: a
+				// Ready that never succeeds is still a started object that
+				// rollback/Stop needs to unwind.
+				startedMu.Lock()
+				started = append(started, o)
+				startedMu.Unlock()
+
+				if readyO, ok := o.Value.(Ready); ok {
+					if g.Logger != nil {
+						g.Logger.Debugf("waiting for %s to become ready", o)
+					}
+					if err := g.waitReady(objCtx, o, readyO); err != nil {
+						return &objectStartError{object: o, err: err}
+					}
 				}
+				return nil
+			})
+		}
+
+		err := eg.Wait()
+		cancel()
+		if err != nil {
+			startErr := &StartError{Cause: err}
+			var ose *objectStartError
+			if errors.As(err, &ose) {
+				startErr.Object = ose.object
+				startErr.Cause = ose.err
+			}
+			if !g.DisableRollbackOnStartError {
+				startErr.RollbackErrors = g.rollback(started)
+				// rollback already stopped everything in started, so a
+				// follow-up Stop() must be a no-op rather than stopping
+				// it all a second time.
+				g.started = nil
+			} else {
+				g.started = started
 			}
-			started = append(started, o)
+			return startErr
 		}
 	}
 	g.started = started
 	return nil
 }
 
+// waitReady polls r.Ready until it returns nil or ctx is done, backing off
+// between attempts according to Graph.ReadyPollInterval/ReadyPollBackoff.
+func (g *Graph) waitReady(ctx context.Context, o *Dew, r Ready) error {
+	interval := g.ReadyPollInterval
+	if interval <= 0 {
+		interval = defaultReadyPollInterval
+	}
+	backoff := g.ReadyPollBackoff
+	if backoff < 1 {
+		backoff = 1
+	}
+
+	for {
+		err := r.Ready(ctx)
+		if err == nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s did not become ready: %w", o, err)
+		case <-time.After(interval):
+		}
+		interval = time.Duration(float64(interval) * backoff)
+	}
+}
+
+// rollback stops/closes started, in reverse level order, under a context
+// derived from Graph.RollbackTimeout. It is used to unwind a partially
+// started graph after a Start failure, going through the same
+// instrumented, concurrency-aware, per-object-timeout stop path as a
+// regular Stop so that a failed boot is as observable as a clean
+// shutdown. Unlike stop, it keeps unwinding every level even if one
+// fails, since the point is to tear down as much as possible.
+func (g *Graph) rollback(started []*Dew) []error {
+	ctx := context.Background()
+	if g.RollbackTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, g.RollbackTimeout)
+		defer cancel()
+	}
+
+	rollbackLevels, err := levels(started)
+	if err != nil {
+		return []error{err}
+	}
+
+	var errs []error
+	for _, level := range rollbackLevels {
+		if err := g.stopLevel(ctx, level); err != nil {
+			errs = append(errs, fmt.Errorf("rollback: %w", err))
+		}
+	}
+	return errs
+}
+
 // Start the graph, in the right order. Start will call Start or Open if an
 // object satisfies the associated interface.
 func (g *Graph) Start(ctx context.Context) error {
@@ -97,12 +289,45 @@ func (g *Graph) stop(ctx context.Context) error {
 	}
 
 	for _, level := range levels {
-		for _, o := range level {
+		if err := g.stopLevel(ctx, level); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stopLevel stops/closes every object in level, fanned out per
+// Graph.Concurrency, honoring each object's StopTimeout (falling back to
+// ctx) and instrumenting every call via Hooks/TracerProvider. An error
+// from one object cancels the level-scoped context so siblings in the
+// same level can bail early; it's left to the caller to decide whether
+// an error here should stop remaining levels (stop does, rollback
+// doesn't).
+func (g *Graph) stopLevel(ctx context.Context, level []*Dew) error {
+	levelCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	eg, egCtx := errgroup.WithContext(levelCtx)
+	if g.Concurrency > 0 {
+		eg.SetLimit(g.Concurrency)
+	}
+
+	for _, o := range level {
+		o := o
+		eg.Go(func() error {
+			objCtx := egCtx
+			if sto, ok := o.Value.(StopTimeout); ok {
+				if d := sto.StopTimeout(); d > 0 {
+					var objCancel context.CancelFunc
+					objCtx, objCancel = context.WithTimeout(egCtx, d)
+					defer objCancel()
+				}
+			}
+
 			if stopperO, ok := o.Value.(Stopper); ok {
 				if g.Logger != nil {
 					g.Logger.Debugf("stopping %s", o)
 				}
-				if err := stopperO.Stop(ctx); err != nil {
+				if err := g.instrument(objCtx, o, "stop", true, stopperO.Stop); err != nil {
 					if g.Logger != nil {
 						g.Logger.Errorf("error stopping %s: %s", o, err)
 					}
@@ -113,16 +338,18 @@ func (g *Graph) stop(ctx context.Context) error {
 				if g.Logger != nil {
 					g.Logger.Debugf("closing %s", o)
 				}
-				if err := closerO.Close(ctx); err != nil {
+				if err := g.instrument(objCtx, o, "close", true, closerO.Close); err != nil {
 					if g.Logger != nil {
 						g.Logger.Errorf("error closing %s: %s", o, err)
 					}
 					return err
 				}
 			}
-		}
+			return nil
+		})
 	}
-	return nil
+
+	return eg.Wait()
 }
 
 // levels returns a slice of levels of objects of the Object Graph that
@@ -256,6 +483,10 @@ func withTimeout(ctx context.Context, f func(context.Context) error) error {
 	}
 }
 
+// Run starts the graph, then blocks until it receives SIGINT or SIGTERM, at
+// which point it stops the graph and returns. While running, it also
+// listens for the signals in Graph.Signals (just SIGHUP when that is
+// empty) and, on receipt, calls Reload instead of shutting down.
 func (g *Graph) Run() {
 	startCtx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
 	defer cancel()
@@ -266,16 +497,71 @@ func (g *Graph) Run() {
 		}
 		return
 	}
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, syscall.SIGINT, syscall.SIGTERM)
-	<-c
 
-	stopCtx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
-	defer cancel()
+	shutdown := make(chan os.Signal, 1)
+	signal.Notify(shutdown, syscall.SIGINT, syscall.SIGTERM)
 
-	if err := g.Stop(stopCtx); err != nil {
-		if g.Logger != nil {
-			g.Logger.Errorf("ERROR\t\tFailed to stop cleanly: %v", err)
+	reloadSignals := g.Signals
+	if len(reloadSignals) == 0 {
+		reloadSignals = []os.Signal{syscall.SIGHUP}
+	}
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, reloadSignals...)
+
+	for {
+		select {
+		case <-reload:
+			if err := g.Reload(context.Background()); err != nil && g.Logger != nil {
+				g.Logger.Errorf("ERROR\t\tFailed to reload cleanly: %v", err)
+			}
+		case <-shutdown:
+			stopCtx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+			defer cancel()
+
+			if err := g.Stop(stopCtx); err != nil {
+				if g.Logger != nil {
+					g.Logger.Errorf("ERROR\t\tFailed to stop cleanly: %v", err)
+				}
+			}
+			return
+		}
+	}
+}
+
+// Reload walks the started objects, in start order, invoking Reload on
+// every one that implements Reloader. A failing Reload is logged but does
+// not stop the walk or tear down the graph; all such errors are joined and
+// returned so that embedders (HTTP admin endpoints, tests) can inspect them.
+func (g *Graph) Reload(ctx context.Context) error {
+	reloadCtx := ctx
+	if g.ReloadTimeout > 0 {
+		var cancel context.CancelFunc
+		reloadCtx, cancel = context.WithTimeout(ctx, g.ReloadTimeout)
+		defer cancel()
+	}
+
+	reloadLevels, err := levels(g.started)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for i := len(reloadLevels) - 1; i >= 0; i-- {
+		for _, o := range reloadLevels[i] {
+			reloaderO, ok := o.Value.(Reloader)
+			if !ok {
+				continue
+			}
+			if g.Logger != nil {
+				g.Logger.Debugf("reloading %s", o)
+			}
+			if err := reloaderO.Reload(reloadCtx); err != nil {
+				if g.Logger != nil {
+					g.Logger.Errorf("error reloading %s: %s", o, err)
+				}
+				errs = append(errs, fmt.Errorf("reloading %s: %w", o, err))
+			}
 		}
 	}
+	return errors.Join(errs...)
 }
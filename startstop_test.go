@@ -0,0 +1,575 @@
+package summer
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// timedComponent records when Start was called and when it returned, after
+// sleeping for delay. Each instance is only ever touched by the goroutine
+// that starts it, so no synchronization is needed around its fields.
+type timedComponent struct {
+	delay     time.Duration
+	startedAt time.Time
+	endedAt   time.Time
+}
+
+func (c *timedComponent) Start(ctx context.Context) error {
+	c.startedAt = time.Now()
+	time.Sleep(c.delay)
+	c.endedAt = time.Now()
+	return nil
+}
+
+func TestTryStartLevelBarrier(t *testing.T) {
+	a1 := &timedComponent{delay: 20 * time.Millisecond}
+	a2 := &timedComponent{delay: 40 * time.Millisecond}
+	b := &timedComponent{}
+
+	dewA1 := &Dew{Value: a1}
+	dewA2 := &Dew{Value: a2}
+	dewB := &Dew{
+		Value: b,
+		Dependencies: []*Dependence{
+			{Field: "A1", Object: dewA1},
+			{Field: "A2", Object: dewA2},
+		},
+	}
+
+	g := &Graph{}
+	g.Add(dewA1)
+	g.Add(dewA2)
+	g.Add(dewB)
+
+	if err := g.tryStart(context.Background()); err != nil {
+		t.Fatalf("tryStart: %v", err)
+	}
+
+	if b.startedAt.Before(a1.endedAt) {
+		t.Errorf("b started before a1 finished")
+	}
+	if b.startedAt.Before(a2.endedAt) {
+		t.Errorf("b started before a2 finished")
+	}
+}
+
+func TestTryStartLevelFansOut(t *testing.T) {
+	slow := &timedComponent{delay: 300 * time.Millisecond}
+	fast := &timedComponent{delay: 150 * time.Millisecond}
+	next := &timedComponent{}
+
+	dewSlow := &Dew{Value: slow}
+	dewFast := &Dew{Value: fast}
+	dewNext := &Dew{
+		Value: next,
+		Dependencies: []*Dependence{
+			{Field: "Slow", Object: dewSlow},
+			{Field: "Fast", Object: dewFast},
+		},
+	}
+
+	g := &Graph{}
+	g.Add(dewSlow)
+	g.Add(dewFast)
+	g.Add(dewNext)
+
+	start := time.Now()
+	if err := g.tryStart(context.Background()); err != nil {
+		t.Fatalf("tryStart: %v", err)
+	}
+
+	elapsed := next.startedAt.Sub(start)
+	if elapsed < slow.delay {
+		t.Errorf("next level started after %s, before the slowest sibling's %s", elapsed, slow.delay)
+	}
+	if bound := slow.delay + fast.delay/2; elapsed > bound {
+		t.Errorf("next level started after %s, exceeding %s as if siblings ran sequentially (sum %s)", elapsed, bound, slow.delay+fast.delay)
+	}
+}
+
+// timedStopComponent records when Stop was called and when it returned,
+// after sleeping for delay. Each instance is only ever touched by the
+// goroutine that stops it, so no synchronization is needed around its
+// fields.
+type timedStopComponent struct {
+	delay     time.Duration
+	startedAt time.Time
+	endedAt   time.Time
+}
+
+func (c *timedStopComponent) Start(ctx context.Context) error {
+	return nil
+}
+
+func (c *timedStopComponent) Stop(ctx context.Context) error {
+	c.startedAt = time.Now()
+	time.Sleep(c.delay)
+	c.endedAt = time.Now()
+	return nil
+}
+
+func TestStopLevelBarrier(t *testing.T) {
+	a1 := &timedStopComponent{}
+	a2 := &timedStopComponent{}
+	b := &timedStopComponent{delay: 20 * time.Millisecond}
+
+	dewA1 := &Dew{Value: a1}
+	dewA2 := &Dew{Value: a2}
+	dewB := &Dew{
+		Value: b,
+		Dependencies: []*Dependence{
+			{Field: "A1", Object: dewA1},
+			{Field: "A2", Object: dewA2},
+		},
+	}
+
+	g := &Graph{}
+	g.Add(dewA1)
+	g.Add(dewA2)
+	g.Add(dewB)
+
+	if err := g.tryStart(context.Background()); err != nil {
+		t.Fatalf("tryStart: %v", err)
+	}
+	if err := g.stop(context.Background()); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+
+	if a1.startedAt.Before(b.endedAt) {
+		t.Errorf("a1 stopped before b finished stopping")
+	}
+	if a2.startedAt.Before(b.endedAt) {
+		t.Errorf("a2 stopped before b finished stopping")
+	}
+}
+
+func TestStopLevelFansOut(t *testing.T) {
+	slow := &timedStopComponent{delay: 300 * time.Millisecond}
+	fast := &timedStopComponent{delay: 150 * time.Millisecond}
+	next := &timedStopComponent{}
+
+	dewSlow := &Dew{Value: slow}
+	dewFast := &Dew{Value: fast}
+	dewNext := &Dew{
+		Value: next,
+		Dependencies: []*Dependence{
+			{Field: "Slow", Object: dewSlow},
+			{Field: "Fast", Object: dewFast},
+		},
+	}
+
+	g := &Graph{}
+	g.Add(dewSlow)
+	g.Add(dewFast)
+	g.Add(dewNext)
+
+	if err := g.tryStart(context.Background()); err != nil {
+		t.Fatalf("tryStart: %v", err)
+	}
+
+	start := time.Now()
+	if err := g.stop(context.Background()); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	elapsed := time.Since(start)
+
+	if elapsed < slow.delay {
+		t.Errorf("stop took %s, less than the slowest sibling's %s", elapsed, slow.delay)
+	}
+	if bound := slow.delay + fast.delay/2; elapsed > bound {
+		t.Errorf("stop took %s, exceeding %s as if siblings ran sequentially (sum %s)", elapsed, bound, slow.delay+fast.delay)
+	}
+}
+
+// concurrencyTrackingComponent records, via a shared pair of counters, the
+// peak number of instances whose Start was in flight at once.
+type concurrencyTrackingComponent struct {
+	delay   time.Duration
+	current *int32
+	peak    *int32
+}
+
+func (c *concurrencyTrackingComponent) Start(ctx context.Context) error {
+	n := atomic.AddInt32(c.current, 1)
+	for {
+		p := atomic.LoadInt32(c.peak)
+		if n <= p || atomic.CompareAndSwapInt32(c.peak, p, n) {
+			break
+		}
+	}
+	time.Sleep(c.delay)
+	atomic.AddInt32(c.current, -1)
+	return nil
+}
+
+func TestTryStartConcurrencyBounds(t *testing.T) {
+	newLevel := func(n int, delay time.Duration) (*Graph, *int32) {
+		var current, peak int32
+		g := &Graph{}
+		for i := 0; i < n; i++ {
+			g.Add(&Dew{Value: &concurrencyTrackingComponent{delay: delay, current: &current, peak: &peak}})
+		}
+		return g, &peak
+	}
+
+	t.Run("Concurrency 1 serializes", func(t *testing.T) {
+		g, peak := newLevel(4, 10*time.Millisecond)
+		g.Concurrency = 1
+		if err := g.tryStart(context.Background()); err != nil {
+			t.Fatalf("tryStart: %v", err)
+		}
+		if got := atomic.LoadInt32(peak); got != 1 {
+			t.Errorf("expected at most 1 concurrent Start with Concurrency=1, saw peak %d", got)
+		}
+	})
+
+	t.Run("Concurrency 2 caps a larger level", func(t *testing.T) {
+		g, peak := newLevel(4, 10*time.Millisecond)
+		g.Concurrency = 2
+		if err := g.tryStart(context.Background()); err != nil {
+			t.Fatalf("tryStart: %v", err)
+		}
+		if got := atomic.LoadInt32(peak); got != 2 {
+			t.Errorf("expected exactly 2 concurrent Starts with Concurrency=2, saw peak %d", got)
+		}
+	})
+}
+
+// stoppableComponent starts successfully, optionally fails to start, and
+// records whether Stop was called on it.
+type stoppableComponent struct {
+	failStart bool
+	stopped   bool
+}
+
+func (c *stoppableComponent) Start(ctx context.Context) error {
+	if c.failStart {
+		return errors.New("boom")
+	}
+	return nil
+}
+
+func (c *stoppableComponent) Stop(ctx context.Context) error {
+	c.stopped = true
+	return nil
+}
+
+func TestTryStartRollsBackOnError(t *testing.T) {
+	ok := &stoppableComponent{}
+	bad := &stoppableComponent{failStart: true}
+
+	dewOK := &Dew{Value: ok}
+	dewBad := &Dew{
+		Value: bad,
+		Dependencies: []*Dependence{
+			{Field: "OK", Object: dewOK},
+		},
+	}
+
+	g := &Graph{}
+	g.Add(dewOK)
+	g.Add(dewBad)
+
+	err := g.tryStart(context.Background())
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	var startErr *StartError
+	if !errors.As(err, &startErr) {
+		t.Fatalf("expected *StartError, got %T: %v", err, err)
+	}
+	if startErr.Object != dewBad {
+		t.Errorf("expected failing object to be dewBad, got %v", startErr.Object)
+	}
+	if !ok.stopped {
+		t.Errorf("expected the already-started object to be rolled back")
+	}
+}
+
+func TestTryStartNoRollbackWhenDisabled(t *testing.T) {
+	ok := &stoppableComponent{}
+	bad := &stoppableComponent{failStart: true}
+
+	dewOK := &Dew{Value: ok}
+	dewBad := &Dew{
+		Value: bad,
+		Dependencies: []*Dependence{
+			{Field: "OK", Object: dewOK},
+		},
+	}
+
+	g := &Graph{DisableRollbackOnStartError: true}
+	g.Add(dewOK)
+	g.Add(dewBad)
+
+	if err := g.tryStart(context.Background()); err == nil {
+		t.Fatal("expected an error")
+	}
+	if ok.stopped {
+		t.Errorf("expected no rollback when DisableRollbackOnStartError is true")
+	}
+}
+
+// slowReadyComponent starts immediately but only reports Ready after
+// readyAfter has elapsed.
+type slowReadyComponent struct {
+	readyAfter time.Time
+}
+
+func (c *slowReadyComponent) Start(ctx context.Context) error {
+	return nil
+}
+
+func (c *slowReadyComponent) Ready(ctx context.Context) error {
+	if time.Now().Before(c.readyAfter) {
+		return errors.New("not ready yet")
+	}
+	return nil
+}
+
+func TestTryStartWaitsForReady(t *testing.T) {
+	comp := &slowReadyComponent{readyAfter: time.Now().Add(50 * time.Millisecond)}
+	g := &Graph{ReadyPollInterval: 5 * time.Millisecond}
+	g.Add(&Dew{Value: comp})
+
+	start := time.Now()
+	if err := g.tryStart(context.Background()); err != nil {
+		t.Fatalf("tryStart: %v", err)
+	}
+	if time.Since(start) < 50*time.Millisecond {
+		t.Errorf("tryStart returned before the component reported ready")
+	}
+}
+
+// neverReadyComponent starts successfully but never becomes ready, and
+// records whether Stop was called on it.
+type neverReadyComponent struct {
+	stopped bool
+}
+
+func (c *neverReadyComponent) Start(ctx context.Context) error {
+	return nil
+}
+
+func (c *neverReadyComponent) Ready(ctx context.Context) error {
+	return errors.New("never ready")
+}
+
+func (c *neverReadyComponent) Stop(ctx context.Context) error {
+	c.stopped = true
+	return nil
+}
+
+func TestTryStartRollsBackNeverReadyComponent(t *testing.T) {
+	comp := &neverReadyComponent{}
+	g := &Graph{ReadyPollInterval: time.Millisecond}
+	g.Add(&Dew{Value: comp})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := g.tryStart(ctx); err == nil {
+		t.Fatal("expected an error once the start timeout cuts the Ready poll short")
+	}
+	if !comp.stopped {
+		t.Errorf("expected the never-ready component's Stop to be called during rollback")
+	}
+}
+
+// timeoutComponent implements StartTimeout and fails if Start runs longer
+// than that timeout allows the parent context to survive.
+type timeoutComponent struct {
+	timeout time.Duration
+	delay   time.Duration
+}
+
+func (c *timeoutComponent) StartTimeout() time.Duration {
+	return c.timeout
+}
+
+func (c *timeoutComponent) Start(ctx context.Context) error {
+	select {
+	case <-time.After(c.delay):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestTryStartPerObjectTimeout(t *testing.T) {
+	comp := &timeoutComponent{timeout: 10 * time.Millisecond, delay: 100 * time.Millisecond}
+	g := &Graph{}
+	g.Add(&Dew{Value: comp})
+
+	err := g.tryStart(context.Background())
+	if err == nil {
+		t.Fatal("expected the per-object StartTimeout to cut Start short")
+	}
+}
+
+// reloadableComponent records whether Reload was called on it, optionally
+// failing.
+type reloadableComponent struct {
+	reloaded bool
+	fail     bool
+}
+
+func (c *reloadableComponent) Start(ctx context.Context) error {
+	return nil
+}
+
+func (c *reloadableComponent) Reload(ctx context.Context) error {
+	c.reloaded = true
+	if c.fail {
+		return errors.New("reload boom")
+	}
+	return nil
+}
+
+func TestReloadInvokesReloaderAndKeepsGoing(t *testing.T) {
+	bad := &reloadableComponent{fail: true}
+	dewBad := &Dew{Value: bad}
+	good := &reloadableComponent{}
+	dewGood := &Dew{
+		Value: good,
+		Dependencies: []*Dependence{
+			{Field: "Bad", Object: dewBad},
+		},
+	}
+
+	g := &Graph{}
+	g.Add(dewBad)
+	g.Add(dewGood)
+
+	if err := g.tryStart(context.Background()); err != nil {
+		t.Fatalf("tryStart: %v", err)
+	}
+
+	err := g.Reload(context.Background())
+	if err == nil {
+		t.Fatal("expected the failing Reload to be reported")
+	}
+	if !good.reloaded {
+		t.Errorf("expected the dependent's Reload to still run after an earlier one failed")
+	}
+}
+
+func TestHooksFireAroundStartAndStop(t *testing.T) {
+	var phases []string
+
+	g := &Graph{
+		Hooks: &Hooks{
+			BeforeStart: func(ctx context.Context, o *Dew, phase string, err error, dur time.Duration) {
+				phases = append(phases, "before-"+phase)
+			},
+			AfterStart: func(ctx context.Context, o *Dew, phase string, err error, dur time.Duration) {
+				phases = append(phases, "after-"+phase)
+			},
+			BeforeStop: func(ctx context.Context, o *Dew, phase string, err error, dur time.Duration) {
+				phases = append(phases, "before-"+phase)
+			},
+			AfterStop: func(ctx context.Context, o *Dew, phase string, err error, dur time.Duration) {
+				phases = append(phases, "after-"+phase)
+			},
+		},
+	}
+	g.Add(&Dew{Value: &stoppableComponent{}})
+
+	if err := g.tryStart(context.Background()); err != nil {
+		t.Fatalf("tryStart: %v", err)
+	}
+	if err := g.stop(context.Background()); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+
+	want := []string{"before-start", "after-start", "before-stop", "after-stop"}
+	if len(phases) != len(want) {
+		t.Fatalf("expected phases %v, got %v", want, phases)
+	}
+	for i, p := range want {
+		if phases[i] != p {
+			t.Errorf("phase %d: expected %q, got %q", i, p, phases[i])
+		}
+	}
+}
+
+// fakeTracerCtxKey is the key fakeTracerProvider stamps onto the context
+// it hands back, so a test can confirm instrument threads that context
+// into the wrapped Open/Start/Stop/Close call rather than the original.
+type fakeTracerCtxKey struct{}
+
+// fakeSpan records its own name onto the fakeTracerProvider that created
+// it once End is called.
+type fakeSpan struct {
+	tp   *fakeTracerProvider
+	name string
+}
+
+func (s *fakeSpan) End() {
+	s.tp.ended = append(s.tp.ended, s.name)
+}
+
+// fakeTracerProvider records every span name Start was asked to open and
+// every span name End was subsequently called on.
+type fakeTracerProvider struct {
+	started []string
+	ended   []string
+}
+
+func (tp *fakeTracerProvider) Start(ctx context.Context, name string) (context.Context, Span) {
+	tp.started = append(tp.started, name)
+	return context.WithValue(ctx, fakeTracerCtxKey{}, name), &fakeSpan{tp: tp, name: name}
+}
+
+// ctxRecordingComponent records the value stashed under fakeTracerCtxKey
+// by whatever context Start/Stop are called with.
+type ctxRecordingComponent struct {
+	startCtxVal interface{}
+	stopCtxVal  interface{}
+}
+
+func (c *ctxRecordingComponent) Start(ctx context.Context) error {
+	c.startCtxVal = ctx.Value(fakeTracerCtxKey{})
+	return nil
+}
+
+func (c *ctxRecordingComponent) Stop(ctx context.Context) error {
+	c.stopCtxVal = ctx.Value(fakeTracerCtxKey{})
+	return nil
+}
+
+func TestTracerProviderWrapsStartAndStop(t *testing.T) {
+	tp := &fakeTracerProvider{}
+	comp := &ctxRecordingComponent{}
+	dew := &Dew{Value: comp}
+
+	g := &Graph{TracerProvider: tp}
+	g.Add(dew)
+
+	if err := g.tryStart(context.Background()); err != nil {
+		t.Fatalf("tryStart: %v", err)
+	}
+	if err := g.stop(context.Background()); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+
+	wantStart := "summer.start/" + dew.String()
+	wantStop := "summer.stop/" + dew.String()
+	if len(tp.started) != 2 || tp.started[0] != wantStart || tp.started[1] != wantStop {
+		t.Fatalf("expected spans %v, got %v", []string{wantStart, wantStop}, tp.started)
+	}
+	if len(tp.ended) != 2 {
+		t.Fatalf("expected End to be called for both spans, got %v", tp.ended)
+	}
+
+	if comp.startCtxVal != wantStart {
+		t.Errorf("expected Start to run under the span's own context, got %v", comp.startCtxVal)
+	}
+	if comp.stopCtxVal != wantStop {
+		t.Errorf("expected Stop to run under the span's own context, got %v", comp.stopCtxVal)
+	}
+}
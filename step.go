@@ -0,0 +1,65 @@
+package summer
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrStepNotExists is returned by AddStep/AddNamed when a requires name
+// doesn't correspond to an already-registered step or named object.
+var ErrStepNotExists = errors.New("summer: step does not exist")
+
+// ErrStepAlreadyExists is returned by AddStep/AddNamed when name has
+// already been registered. Allowing a second registration to silently
+// replace the map entry would leave the first Dew live in g.objects with
+// no link to anything, while requires edges split across whichever one
+// happened to be registered at the time.
+var ErrStepAlreadyExists = errors.New("summer: step already exists")
+
+// stepFunc adapts a plain function to the Starter interface, so it can be
+// driven through the same levels()/tryStart machinery as any other object.
+type stepFunc func(ctx context.Context) error
+
+func (f stepFunc) Start(ctx context.Context) error {
+	return f(ctx)
+}
+
+// AddNamed registers v under name, with a synthetic dependency on every Dew
+// previously registered under the names in requires. This lets levels()
+// order named objects exactly as it would order struct-field-injected
+// ones, without requiring a struct type just to express that ordering.
+//
+// It returns ErrStepNotExists if any name in requires hasn't been
+// registered yet. A cycle through named nodes alone is structurally
+// impossible: requires can only point at names already registered, so the
+// node being built here can never appear as one of its own dependencies.
+func (g *Graph) AddNamed(name string, v interface{}, requires ...string) error {
+	if g.named == nil {
+		g.named = map[string]*Dew{}
+	}
+	if _, ok := g.named[name]; ok {
+		return ErrStepAlreadyExists
+	}
+
+	deps := make([]*Dependence, 0, len(requires))
+	for _, req := range requires {
+		o, ok := g.named[req]
+		if !ok {
+			return ErrStepNotExists
+		}
+		deps = append(deps, &Dependence{Field: req, Object: o})
+	}
+
+	o := &Dew{Name: name, Value: v, Dependencies: deps}
+	g.named[name] = o
+	g.Add(o)
+	return nil
+}
+
+// AddStep registers fn as a named step, run via Start once every named
+// prerequisite in requires has started. It's a thin wrapper over AddNamed
+// for imperative tasks (migrations, warm-up jobs) that don't otherwise need
+// a full component.
+func (g *Graph) AddStep(name string, fn func(ctx context.Context) error, requires ...string) error {
+	return g.AddNamed(name, stepFunc(fn), requires...)
+}
@@ -0,0 +1,72 @@
+package summer
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestAddStepOrdersByRequires(t *testing.T) {
+	var order []string
+
+	g := &Graph{}
+	if err := g.AddStep("migrate", func(ctx context.Context) error {
+		order = append(order, "migrate")
+		return nil
+	}); err != nil {
+		t.Fatalf("AddStep migrate: %v", err)
+	}
+	if err := g.AddStep("warm-cache", func(ctx context.Context) error {
+		order = append(order, "warm-cache")
+		return nil
+	}, "migrate"); err != nil {
+		t.Fatalf("AddStep warm-cache: %v", err)
+	}
+
+	if err := g.tryStart(context.Background()); err != nil {
+		t.Fatalf("tryStart: %v", err)
+	}
+
+	if len(order) != 2 || order[0] != "migrate" || order[1] != "warm-cache" {
+		t.Errorf("expected [migrate warm-cache], got %v", order)
+	}
+}
+
+func TestAddStepUnknownRequirement(t *testing.T) {
+	g := &Graph{}
+	err := g.AddStep("warm-cache", func(ctx context.Context) error { return nil }, "migrate")
+	if !errors.Is(err, ErrStepNotExists) {
+		t.Fatalf("expected ErrStepNotExists, got %v", err)
+	}
+}
+
+func TestAddNamedRegistersUnderName(t *testing.T) {
+	g := &Graph{}
+	comp := &stoppableComponent{}
+	if err := g.AddNamed("svc", comp); err != nil {
+		t.Fatalf("AddNamed: %v", err)
+	}
+
+	if err := g.AddStep("warm-cache", func(ctx context.Context) error { return nil }, "svc"); err != nil {
+		t.Fatalf("AddStep: %v", err)
+	}
+
+	if err := g.tryStart(context.Background()); err != nil {
+		t.Fatalf("tryStart: %v", err)
+	}
+}
+
+func TestAddNamedRejectsDuplicateName(t *testing.T) {
+	g := &Graph{}
+	if err := g.AddNamed("svc", &stoppableComponent{}); err != nil {
+		t.Fatalf("AddNamed: %v", err)
+	}
+
+	err := g.AddNamed("svc", &stoppableComponent{})
+	if !errors.Is(err, ErrStepAlreadyExists) {
+		t.Fatalf("expected ErrStepAlreadyExists, got %v", err)
+	}
+	if len(g.Objects()) != 1 {
+		t.Errorf("expected the rejected registration to leave g.Objects() untouched, got %d objects", len(g.Objects()))
+	}
+}